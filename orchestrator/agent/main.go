@@ -0,0 +1,175 @@
+// Command cads-fmi-agent dials an orchestrator and serves FMU runs on its
+// behalf, so that FMI simulations can be spread across machines instead of
+// all running through the orchestrator's own cgo bridge.
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"net/url"
+	"os"
+	"runtime"
+	"strings"
+	"time"
+
+	"github.com/gorilla/websocket"
+	"github.com/sourcegraph/jsonrpc2"
+	jsonrpc2ws "github.com/sourcegraph/jsonrpc2/websocket"
+
+	"github.com/norceresearch/cads-fmi-demo/orchestrator/internal/fmi"
+	"github.com/norceresearch/cads-fmi-demo/orchestrator/logging"
+	"github.com/norceresearch/cads-fmi-demo/orchestrator/service/workflow"
+)
+
+const heartbeatPeriod = 5 * time.Second
+
+func main() {
+	var orchestrator string
+	var labelsFlag string
+	var fmiLibsFlag string
+	var maxSlots int
+	var jsonLogs bool
+	var logLevelFlag string
+
+	flag.StringVar(&orchestrator, "orchestrator", "ws://localhost:8080/agents/connect", "Orchestrator agent endpoint")
+	flag.StringVar(&labelsFlag, "labels", "", "Comma-separated key=value labels this agent satisfies (e.g. os=linux,has=fmilib)")
+	flag.StringVar(&fmiLibsFlag, "fmi-libs", "", "Comma-separated FMI library names installed on this host")
+	flag.IntVar(&maxSlots, "max-slots", runtime.NumCPU(), "Maximum concurrent FMU runs this agent accepts")
+	flag.BoolVar(&jsonLogs, "json-logs", false, "Emit logs as line-delimited JSON instead of text")
+	flag.StringVar(&logLevelFlag, "log-level", "info", "Minimum log level to emit (trace, debug, info, warn, error)")
+	flag.Parse()
+
+	logLevel, err := logging.ParseLevel(logLevelFlag)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+
+	caps := workflow.AgentCapabilities{
+		OS:       runtime.GOOS,
+		Arch:     runtime.GOARCH,
+		FMILibs:  splitNonEmpty(fmiLibsFlag),
+		MaxSlots: maxSlots,
+		Labels:   parseLabels(labelsFlag),
+	}
+
+	log := logging.New(logging.Options{Name: "agent", Level: logLevel, JSON: jsonLogs})
+
+	backoff := time.Second
+	const maxBackoff = 30 * time.Second
+	for {
+		if err := connectAndServe(orchestrator, caps, log); err != nil {
+			log.Warn("connection lost", "error", err.Error(), "retry_in", backoff.String())
+		}
+		time.Sleep(backoff)
+		if backoff *= 2; backoff > maxBackoff {
+			backoff = maxBackoff
+		}
+	}
+}
+
+// connectAndServe dials the orchestrator once, registers, and blocks
+// serving Run calls until the connection drops.
+func connectAndServe(orchestrator string, caps workflow.AgentCapabilities, log logging.Logger) error {
+	u, err := url.Parse(orchestrator)
+	if err != nil {
+		return fmt.Errorf("parse orchestrator URL: %w", err)
+	}
+
+	ws, _, err := websocket.DefaultDialer.Dial(u.String(), nil)
+	if err != nil {
+		return fmt.Errorf("dial %s: %w", u, err)
+	}
+	defer ws.Close()
+
+	runner := &runHandler{log: log.Named("run")}
+	conn := jsonrpc2.NewConn(context.Background(), jsonrpc2ws.NewObjectStream(ws), jsonrpc2.HandlerWithError(runner.handle))
+	defer conn.Close()
+
+	var registered map[string]string
+	if err := conn.Call(context.Background(), "Register", caps, &registered); err != nil {
+		return fmt.Errorf("register: %w", err)
+	}
+	log.Info("registered", "agent_id", registered["id"], "labels", caps.Labels, "max_slots", caps.MaxSlots)
+
+	stop := make(chan struct{})
+	defer close(stop)
+	go heartbeat(conn, stop)
+
+	<-conn.DisconnectNotify()
+	return fmt.Errorf("connection to %s closed", u)
+}
+
+func heartbeat(conn *jsonrpc2.Conn, stop <-chan struct{}) {
+	ticker := time.NewTicker(heartbeatPeriod)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-stop:
+			return
+		case <-ticker.C:
+			if err := conn.Notify(context.Background(), "Heartbeat", nil); err != nil {
+				return
+			}
+		}
+	}
+}
+
+// runHandler serves the orchestrator's "Run" calls by executing the FMU
+// in-process. An FMU error is reported in the reply rather than as a
+// transport-level failure, so the orchestrator knows not to retry it on
+// another agent.
+type runHandler struct {
+	log logging.Logger
+}
+
+func (h *runHandler) handle(ctx context.Context, conn *jsonrpc2.Conn, req *jsonrpc2.Request) (any, error) {
+	if req.Method != "Run" {
+		return nil, &jsonrpc2.Error{Code: jsonrpc2.CodeMethodNotFound, Message: "unknown method " + req.Method}
+	}
+
+	var args struct {
+		Config fmi.Config `json:"config"`
+	}
+	if req.Params == nil {
+		return nil, &jsonrpc2.Error{Message: "missing params"}
+	}
+	if err := json.Unmarshal(*req.Params, &args); err != nil {
+		return nil, &jsonrpc2.Error{Message: err.Error()}
+	}
+	args.Config.Logger = h.log.With("fmu_path", args.Config.FMUPath)
+
+	outputs, err := fmi.Run(ctx, args.Config)
+	if err != nil {
+		h.log.Error("run failed", "fmu_path", args.Config.FMUPath, "error", err.Error())
+		return map[string]any{"fmu_error": err.Error()}, nil
+	}
+	return map[string]any{"outputs": outputs}, nil
+}
+
+func parseLabels(raw string) map[string]string {
+	labels := make(map[string]string)
+	for _, pair := range splitNonEmpty(raw) {
+		key, value, ok := strings.Cut(pair, "=")
+		if !ok {
+			continue
+		}
+		labels[key] = value
+	}
+	return labels
+}
+
+func splitNonEmpty(raw string) []string {
+	if raw == "" {
+		return nil
+	}
+	var out []string
+	for _, part := range strings.Split(raw, ",") {
+		if part = strings.TrimSpace(part); part != "" {
+			out = append(out, part)
+		}
+	}
+	return out
+}
@@ -0,0 +1,185 @@
+package fmi
+
+/*
+#cgo CXXFLAGS: -std=c++17
+#cgo LDFLAGS: -lfmilib_shared -lpugixml -lzip -lm -ldl -lstdc++
+#include <stdlib.h>
+#include "runner_bridge.h"
+*/
+import "C"
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"unsafe"
+
+	"github.com/norceresearch/cads-fmi-demo/orchestrator/logging"
+)
+
+// Config describes a single FMU execution.
+type Config struct {
+	FMUPath     string
+	StartTime   *float64
+	StopTime    *float64
+	StepSize    *float64
+	StartValues map[string]string
+	Outputs     []string
+
+	// Logger receives this run's events: Debug for the individual FMI
+	// calls, Warn on cancellation and Error on failure. A nil Logger
+	// discards them. It never needs to survive a wire round trip: the
+	// agent's Run handler attaches its own logger to the Config it
+	// receives, and a non-nil
+	// interface value with unexported fields fails to unmarshal back
+	// into it anyway, so it is excluded from JSON.
+	Logger logging.Logger `json:"-"`
+}
+
+// runResult is what the worker goroutine in Run hands back once
+// cads_run_fmu_wait returns, so the caller goroutine can select on it
+// alongside ctx.Done() without touching C memory from two goroutines.
+type runResult struct {
+	code    C.int
+	jsonOut *C.char
+	errOut  *C.char
+}
+
+// Run executes the FMU using FMIL and returns the final snapshot of
+// requested outputs. The blocking simulation runs on a dedicated
+// goroutine; if ctx is cancelled first, Run asks FMIL to stop between
+// simulation steps via cads_run_fmu_cancel and waits for that goroutine
+// to actually return before freeing any C memory the FMU might still be
+// reading, so a cancellation never races a use-after-free.
+func Run(ctx context.Context, cfg Config) (map[string]any, error) {
+	log := cfg.Logger
+	if log == nil {
+		log = logging.NewNop()
+	}
+
+	if cfg.FMUPath == "" {
+		return nil, fmt.Errorf("fmi: FMU path is required")
+	}
+
+	cCfg := C.cads_fmu_config{}
+	cPath := C.CString(cfg.FMUPath)
+	defer C.free(unsafe.Pointer(cPath))
+	cCfg.fmu_path = cPath
+
+	if cfg.StartTime != nil {
+		cCfg.has_start_time = true
+		cCfg.start_time = C.double(*cfg.StartTime)
+	}
+	if cfg.StopTime != nil {
+		cCfg.has_stop_time = true
+		cCfg.stop_time = C.double(*cfg.StopTime)
+	}
+	if cfg.StepSize != nil {
+		cCfg.has_step_size = true
+		cCfg.step_size = C.double(*cfg.StepSize)
+	}
+
+	var assignmentBacking []*C.char
+	if len(cfg.StartValues) > 0 {
+		keys := make([]string, 0, len(cfg.StartValues))
+		for k := range cfg.StartValues {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		mem := C.malloc(C.size_t(len(keys)) * C.size_t(C.sizeof_cads_assignment))
+		if mem == nil {
+			return nil, fmt.Errorf("fmi: failed to allocate start value buffer")
+		}
+		defer C.free(mem)
+		assignments := unsafe.Slice((*C.cads_assignment)(mem), len(keys))
+		for i, key := range keys {
+			value := cfg.StartValues[key]
+			nameC := C.CString(key)
+			valueC := C.CString(value)
+			assignmentBacking = append(assignmentBacking, nameC, valueC)
+			assignments[i] = C.cads_assignment{name: nameC, value: valueC}
+		}
+		cCfg.start_values = (*C.cads_assignment)(mem)
+		cCfg.start_value_count = C.size_t(len(keys))
+	}
+
+	if len(cfg.Outputs) > 0 {
+		ptrSize := unsafe.Sizeof((*C.char)(nil))
+		mem := C.malloc(C.size_t(len(cfg.Outputs)) * C.size_t(ptrSize))
+		if mem == nil {
+			return nil, fmt.Errorf("fmi: failed to allocate outputs buffer")
+		}
+		defer C.free(mem)
+		outputPtrs := unsafe.Slice((**C.char)(mem), len(cfg.Outputs))
+		for i, name := range cfg.Outputs {
+			cstr := C.CString(name)
+			assignmentBacking = append(assignmentBacking, cstr)
+			outputPtrs[i] = cstr
+		}
+		cCfg.outputs = (**C.char)(mem)
+		cCfg.output_count = C.size_t(len(outputPtrs))
+	}
+
+	// assignmentBacking must outlive the worker goroutine below, not just
+	// this call: if ctx is cancelled, the FMU may still be reading these
+	// pointers between simulation steps until cads_run_fmu_cancel takes
+	// effect. Free them only after the worker actually returns.
+	defer func() {
+		for _, ptr := range assignmentBacking {
+			C.free(unsafe.Pointer(ptr))
+		}
+	}()
+
+	log.Debug("invoking cads_run_fmu", "fmu_path", cfg.FMUPath, "start_values", len(cfg.StartValues), "outputs", len(cfg.Outputs))
+
+	handle := C.cads_run_fmu_begin(&cCfg)
+	if handle == nil {
+		return nil, fmt.Errorf("fmi: failed to start FMU run")
+	}
+	defer C.cads_run_fmu_free(handle)
+
+	done := make(chan runResult, 1)
+	go func() {
+		var res runResult
+		res.code = C.cads_run_fmu_wait(handle, &res.jsonOut, &res.errOut)
+		done <- res
+	}()
+
+	var res runResult
+	select {
+	case res = <-done:
+	case <-ctx.Done():
+		log.Warn("cancelling cads_run_fmu", "fmu_path", cfg.FMUPath, "reason", ctx.Err().Error())
+		C.cads_run_fmu_cancel(handle)
+		res = <-done // wait for the worker to actually stop before cCfg/assignments are freed above
+		if res.code == 0 {
+			if res.jsonOut != nil {
+				C.cads_free_string(res.jsonOut)
+			}
+		}
+		if res.errOut != nil {
+			C.cads_free_string(res.errOut)
+		}
+		return nil, ctx.Err()
+	}
+
+	if res.code != 0 {
+		if res.errOut != nil {
+			defer C.cads_free_string(res.errOut)
+			msg := C.GoString(res.errOut)
+			log.Error("cads_run_fmu failed", "fmu_path", cfg.FMUPath, "error", msg)
+			return nil, fmt.Errorf("fmi runner: %s", msg)
+		}
+		log.Error("cads_run_fmu failed without error message", "fmu_path", cfg.FMUPath)
+		return nil, fmt.Errorf("fmi runner failed without error message")
+	}
+	defer C.cads_free_string(res.jsonOut)
+
+	var parsed map[string]any
+	if err := json.Unmarshal([]byte(C.GoString(res.jsonOut)), &parsed); err != nil {
+		return nil, fmt.Errorf("decode FMU result: %w", err)
+	}
+	log.Debug("cads_run_fmu completed", "fmu_path", cfg.FMUPath, "output_count", len(parsed))
+	return parsed, nil
+}
@@ -0,0 +1,184 @@
+// Package logging provides a small, leveled logging interface shared by
+// the orchestrator, its workflow engine and the cgo FMI bridge. It is
+// modeled on hclog: named sub-loggers carry key/value fields that are
+// attached to every message logged through them, so a caller can narrow
+// down output to a single workflow run or step without grepping.
+package logging
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Level is a logging severity, ordered from most to least verbose.
+type Level int
+
+const (
+	Trace Level = iota
+	Debug
+	Info
+	Warn
+	Error
+)
+
+func (l Level) String() string {
+	switch l {
+	case Trace:
+		return "trace"
+	case Debug:
+		return "debug"
+	case Info:
+		return "info"
+	case Warn:
+		return "warn"
+	case Error:
+		return "error"
+	default:
+		return "unknown"
+	}
+}
+
+// ParseLevel parses a level name (case-insensitive) as accepted on the
+// command line, e.g. "info" or "DEBUG". It is the inverse of String.
+func ParseLevel(name string) (Level, error) {
+	switch strings.ToLower(name) {
+	case "trace":
+		return Trace, nil
+	case "debug":
+		return Debug, nil
+	case "info":
+		return Info, nil
+	case "warn":
+		return Warn, nil
+	case "error":
+		return Error, nil
+	default:
+		return 0, fmt.Errorf("logging: unknown level %q (want trace, debug, info, warn, or error)", name)
+	}
+}
+
+// Logger is the interface every subsystem logs through.
+type Logger interface {
+	Trace(msg string, args ...any)
+	Debug(msg string, args ...any)
+	Info(msg string, args ...any)
+	Warn(msg string, args ...any)
+	Error(msg string, args ...any)
+
+	// With returns a sub-logger that attaches the given key/value pairs
+	// to every message logged through it, in addition to any fields
+	// already attached to this logger.
+	With(args ...any) Logger
+
+	// Named returns a sub-logger whose name is this logger's name with
+	// name appended (dot-separated), e.g. Named("executor") on a logger
+	// named "service" produces "service.executor".
+	Named(name string) Logger
+}
+
+// Options configures a root Logger. The zero value of Level is Trace, the
+// most verbose level, so callers that want the normal default must set
+// Level explicitly (most should pass Info and let a flag override it).
+type Options struct {
+	Name   string
+	Level  Level
+	Output io.Writer // defaults to os.Stderr
+	JSON   bool      // line-delimited JSON instead of human-readable text
+}
+
+// New creates a root Logger.
+func New(opts Options) Logger {
+	out := opts.Output
+	if out == nil {
+		out = os.Stderr
+	}
+	return &logger{mu: &sync.Mutex{}, out: out, name: opts.Name, level: opts.Level, json: opts.JSON}
+}
+
+// NewNop returns a Logger that discards everything. Useful as a default
+// for callers that don't configure logging.
+func NewNop() Logger {
+	return New(Options{Output: io.Discard})
+}
+
+type logger struct {
+	mu     *sync.Mutex
+	out    io.Writer
+	name   string
+	fields []any
+	level  Level
+	json   bool
+}
+
+func (l *logger) log(level Level, msg string, args []any) {
+	if level < l.level {
+		return
+	}
+	fields := make([]any, 0, len(l.fields)+len(args))
+	fields = append(fields, l.fields...)
+	fields = append(fields, args...)
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if l.json {
+		l.writeJSON(level, msg, fields)
+	} else {
+		l.writeText(level, msg, fields)
+	}
+}
+
+func (l *logger) writeText(level Level, msg string, fields []any) {
+	var b strings.Builder
+	fmt.Fprintf(&b, "%s [%-5s]", time.Now().Format(time.RFC3339), strings.ToUpper(level.String()))
+	if l.name != "" {
+		fmt.Fprintf(&b, " %s:", l.name)
+	}
+	fmt.Fprintf(&b, " %s", msg)
+	for i := 0; i+1 < len(fields); i += 2 {
+		fmt.Fprintf(&b, " %v=%v", fields[i], fields[i+1])
+	}
+	fmt.Fprintln(l.out, b.String())
+}
+
+func (l *logger) writeJSON(level Level, msg string, fields []any) {
+	entry := map[string]any{
+		"timestamp": time.Now().Format(time.RFC3339),
+		"level":     level.String(),
+		"message":   msg,
+	}
+	if l.name != "" {
+		entry["logger"] = l.name
+	}
+	for i := 0; i+1 < len(fields); i += 2 {
+		entry[fmt.Sprintf("%v", fields[i])] = fields[i+1]
+	}
+	enc := json.NewEncoder(l.out)
+	_ = enc.Encode(entry)
+}
+
+func (l *logger) Trace(msg string, args ...any) { l.log(Trace, msg, args) }
+func (l *logger) Debug(msg string, args ...any) { l.log(Debug, msg, args) }
+func (l *logger) Info(msg string, args ...any)  { l.log(Info, msg, args) }
+func (l *logger) Warn(msg string, args ...any)  { l.log(Warn, msg, args) }
+func (l *logger) Error(msg string, args ...any) { l.log(Error, msg, args) }
+
+func (l *logger) With(args ...any) Logger {
+	next := *l
+	next.fields = append(append([]any{}, l.fields...), args...)
+	return &next
+}
+
+func (l *logger) Named(name string) Logger {
+	next := *l
+	if next.name == "" {
+		next.name = name
+	} else {
+		next.name = next.name + "." + name
+	}
+	return &next
+}
@@ -0,0 +1,86 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sync/atomic"
+
+	"github.com/gorilla/websocket"
+	"github.com/sourcegraph/jsonrpc2"
+	jsonrpc2ws "github.com/sourcegraph/jsonrpc2/websocket"
+
+	"github.com/norceresearch/cads-fmi-demo/orchestrator/logging"
+	"github.com/norceresearch/cads-fmi-demo/orchestrator/service/workflow"
+)
+
+var wsUpgrader = websocket.Upgrader{
+	ReadBufferSize:  4096,
+	WriteBufferSize: 4096,
+}
+
+var agentSeq int64
+
+// AgentHub accepts the persistent JSON-RPC 2.0 connections opened by
+// orchestrator/agent processes and keeps them registered in a pool the
+// workflow executor's RemoteDispatcher can schedule steps onto.
+type AgentHub struct {
+	Pool *workflow.AgentPool
+	Log  logging.Logger
+}
+
+func (h *AgentHub) log() logging.Logger {
+	if h.Log != nil {
+		return h.Log
+	}
+	return logging.NewNop()
+}
+
+// ServeHTTP upgrades the request to a WebSocket and speaks JSON-RPC 2.0
+// over it for the lifetime of the connection, registering and evicting
+// the agent from the pool as it connects and disconnects.
+func (h *AgentHub) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	conn, err := wsUpgrader.Upgrade(w, r, nil)
+	if err != nil {
+		h.log().Error("agent connect: upgrade failed", "error", err.Error())
+		return
+	}
+
+	id := fmt.Sprintf("agent-%d", atomic.AddInt64(&agentSeq, 1))
+	handler := &agentHandler{id: id, pool: h.Pool, log: h.log().With("agent_id", id)}
+	rpcConn := jsonrpc2.NewConn(context.Background(), jsonrpc2ws.NewObjectStream(conn), handler)
+
+	<-rpcConn.DisconnectNotify()
+	h.Pool.Unregister(id)
+}
+
+// agentHandler answers the two calls an agent makes on its side of the
+// connection: Register once at startup and Heartbeat on an interval.
+type agentHandler struct {
+	id   string
+	pool *workflow.AgentPool
+	log  logging.Logger
+}
+
+func (h *agentHandler) Handle(ctx context.Context, conn *jsonrpc2.Conn, req *jsonrpc2.Request) {
+	switch req.Method {
+	case "Register":
+		var caps workflow.AgentCapabilities
+		if req.Params != nil {
+			if err := json.Unmarshal(*req.Params, &caps); err != nil {
+				if !req.Notif {
+					conn.ReplyWithError(ctx, req.ID, &jsonrpc2.Error{Message: err.Error()})
+				}
+				return
+			}
+		}
+		h.pool.Register(h.id, conn, caps)
+		h.log.Info("agent registered", "os", caps.OS, "arch", caps.Arch, "max_slots", caps.MaxSlots, "labels", caps.Labels)
+		if !req.Notif {
+			conn.Reply(ctx, req.ID, map[string]string{"id": h.id})
+		}
+	case "Heartbeat":
+		h.pool.Heartbeat(h.id)
+	}
+}
@@ -1,12 +1,15 @@
 package main
 
 import (
+	"context"
 	"encoding/json"
 	"flag"
-	"fmt"
 	"log"
 	"os"
+	"os/signal"
+	"runtime"
 
+	"github.com/norceresearch/cads-fmi-demo/orchestrator/logging"
 	svc "github.com/norceresearch/cads-fmi-demo/orchestrator/service"
 	"github.com/norceresearch/cads-fmi-demo/orchestrator/service/workflow"
 )
@@ -14,38 +17,67 @@ import (
 func main() {
 	var workflowPath string
 	var jsonOutput bool
+	var jsonLogs bool
+	var logLevel string
 	var workdir string
+	var maxProcs int
+	var dryRun bool
 
 	flag.StringVar(&workflowPath, "workflow", "workflows/python_chain.yaml", "Workflow YAML to execute")
-	flag.BoolVar(&jsonOutput, "json-output", false, "Only emit the final JSON result")
+	flag.BoolVar(&jsonOutput, "json-output", false, "Only emit the final JSON result on stdout")
+	flag.BoolVar(&jsonLogs, "json-logs", false, "Emit logs as line-delimited JSON instead of text")
+	flag.StringVar(&logLevel, "log-level", "info", "Minimum log level to emit (trace, debug, info, warn, error)")
 	flag.StringVar(&workdir, "workdir", "", "Explicit repository root (optional)")
+	flag.IntVar(&maxProcs, "max-procs", runtime.NumCPU(), "Maximum steps to run concurrently")
+	flag.BoolVar(&dryRun, "dry-run", false, "Print the resolved step order and bindings without running anything")
 	flag.Parse()
 
 	if workflowPath == "" {
 		log.Fatal("workflow path is required")
 	}
 
-	var opts []workflow.Option
-	if !jsonOutput {
-		opts = append(opts, workflow.WithLogger(func(format string, args ...any) {
-			fmt.Printf(format+"\n", args...)
-		}))
+	level, err := logging.ParseLevel(logLevel)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	var opts []svc.Option
+	if jsonLogs {
+		opts = append(opts, svc.WithJSONLogs())
 	}
+	opts = append(opts, svc.WithLogLevel(level))
+	opts = append(opts, svc.WithWorkflowOptions(workflow.WithMaxConcurrency(maxProcs)))
 
 	runner, err := svc.NewRunner(workdir, opts...)
 	if err != nil {
 		log.Fatal(err)
 	}
 
-	if !jsonOutput {
-		fmt.Printf("[workflow] Running %s\n", workflowPath)
+	if dryRun {
+		plan, err := runner.Plan(workflowPath)
+		if err != nil {
+			log.Fatal(err)
+		}
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		if err := enc.Encode(plan); err != nil {
+			log.Fatal(err)
+		}
+		return
 	}
 
-	results, err := runner.Run(workflowPath)
+	runner.Log.Info("running workflow", "workflow", workflowPath)
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+
+	results, err := runner.Run(ctx, workflowPath)
 	if err != nil {
 		log.Fatal(err)
 	}
 
+	runner.Log.Info("workflow completed")
+
 	if jsonOutput {
 		enc := json.NewEncoder(os.Stdout)
 		if err := enc.Encode(results); err != nil {
@@ -54,8 +86,6 @@ func main() {
 		return
 	}
 
-	fmt.Println("[workflow] Completed all steps.")
-
 	enc := json.NewEncoder(os.Stdout)
 	enc.SetIndent("", "  ")
 	if err := enc.Encode(results); err != nil {
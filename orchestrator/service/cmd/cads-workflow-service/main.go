@@ -1,35 +1,91 @@
 package main
 
 import (
+	"context"
 	"encoding/json"
 	"flag"
-	"fmt"
 	"log"
 	"net/http"
 	"os"
+	"os/signal"
+	"runtime"
+	"time"
 
+	"github.com/norceresearch/cads-fmi-demo/orchestrator/logging"
 	svc "github.com/norceresearch/cads-fmi-demo/orchestrator/service"
+	"github.com/norceresearch/cads-fmi-demo/orchestrator/service/workflow"
 )
 
 func main() {
-	var workflow string
+	var workflowPath string
 	var serve bool
 	var addr string
 	var workdir string
+	var agents bool
+	var jsonLogs bool
+	var logLevel string
+	var maxProcs int
+	var dryRun bool
+	var maxRetries int
+	var retryWait time.Duration
 
-	flag.StringVar(&workflow, "workflow", "", "Run the workflow once and exit")
+	flag.StringVar(&workflowPath, "workflow", "", "Run the workflow once and exit")
 	flag.BoolVar(&serve, "serve", false, "Start the HTTP service")
 	flag.StringVar(&addr, "addr", ":8080", "HTTP listen address (default :8080)")
 	flag.StringVar(&workdir, "workdir", "", "Explicit repository root (optional)")
+	flag.BoolVar(&agents, "agents", false, "Accept remote agent connections for steps with run_on labels")
+	flag.BoolVar(&jsonLogs, "json-logs", false, "Emit logs as line-delimited JSON instead of text")
+	flag.StringVar(&logLevel, "log-level", "info", "Minimum log level to emit (trace, debug, info, warn, error)")
+	flag.IntVar(&maxProcs, "max-procs", runtime.NumCPU(), "Maximum steps to run concurrently")
+	flag.BoolVar(&dryRun, "dry-run", false, "With -workflow, print the resolved step order and bindings without running anything")
+	flag.IntVar(&maxRetries, "max-retries", 2, "Times to redispatch a step to a different agent after a transport error")
+	flag.DurationVar(&retryWait, "retry-wait", 0, "Backoff between redispatch attempts (e.g. 500ms, 2s)")
 	flag.Parse()
 
-	runner, err := svc.NewRunner(workdir)
+	level, err := logging.ParseLevel(logLevel)
 	if err != nil {
 		log.Fatal(err)
 	}
 
-	if workflow != "" {
-		results, err := runner.Run(workflow)
+	var opts []svc.Option
+	if jsonLogs {
+		opts = append(opts, svc.WithJSONLogs())
+	}
+	opts = append(opts, svc.WithLogLevel(level))
+	opts = append(opts, svc.WithWorkflowOptions(workflow.WithMaxConcurrency(maxProcs)))
+
+	var pool *workflow.AgentPool
+	if agents {
+		pool = workflow.NewAgentPool()
+		opts = append(opts, svc.WithWorkflowOptions(
+			workflow.WithDispatcher(workflow.NewRemoteDispatcher(pool)),
+			workflow.WithRetry(maxRetries, retryWait),
+		))
+		go pool.StartEvictionLoop(context.Background())
+	}
+
+	runner, err := svc.NewRunner(workdir, opts...)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	if workflowPath != "" {
+		if dryRun {
+			plan, err := runner.Plan(workflowPath)
+			if err != nil {
+				log.Fatal(err)
+			}
+			enc := json.NewEncoder(os.Stdout)
+			enc.SetIndent("", "  ")
+			if err := enc.Encode(plan); err != nil {
+				log.Fatal(err)
+			}
+			return
+		}
+
+		ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+		results, err := runner.Run(ctx, workflowPath)
+		stop()
 		if err != nil {
 			log.Fatal(err)
 		}
@@ -44,12 +100,18 @@ func main() {
 	}
 
 	if serve {
-		server := &svc.Server{Runner: runner}
-		fmt.Printf("[service] listening on %s (workdir %s)\n", addr, runner.WorkDir)
-		log.Fatal(http.ListenAndServe(addr, server))
+		mux := http.NewServeMux()
+		server := &svc.Server{Runner: runner, Log: runner.Log.Named("server")}
+		mux.Handle("/run", server)
+		mux.Handle("/runs/", server)
+		if pool != nil {
+			mux.Handle("/agents/connect", &svc.AgentHub{Pool: pool, Log: runner.Log.Named("agents")})
+		}
+		runner.Log.Info("listening", "addr", addr, "workdir", runner.WorkDir, "agents", agents)
+		log.Fatal(http.ListenAndServe(addr, mux))
 	}
 
-	if workflow == "" && !serve {
+	if workflowPath == "" && !serve {
 		flag.Usage()
 		os.Exit(1)
 	}
@@ -1,35 +1,128 @@
 package service
 
 import (
+	"context"
 	"errors"
 	"fmt"
 	"os"
 	"path/filepath"
 
+	"github.com/norceresearch/cads-fmi-demo/orchestrator/logging"
 	"github.com/norceresearch/cads-fmi-demo/orchestrator/service/workflow"
 )
 
 // Runner executes workflows directly via the Go FMIL bindings.
 type Runner struct {
-	WorkDir string
-	exec    *workflow.Executor
+	WorkDir  string
+	Log      logging.Logger
+	Registry *RunRegistry
+	exec     *workflow.Executor
 }
 
-func NewRunner(workDir string, opts ...workflow.Option) (*Runner, error) {
+// runnerConfig accumulates the options passed to NewRunner.
+type runnerConfig struct {
+	workflowOpts []workflow.Option
+	jsonLogs     bool
+	logLevel     logging.Level
+}
+
+// Option configures a Runner.
+type Option func(*runnerConfig)
+
+// WithWorkflowOptions passes options through to the underlying
+// workflow.Executor, e.g. a dispatcher or retry policy.
+func WithWorkflowOptions(opts ...workflow.Option) Option {
+	return func(c *runnerConfig) {
+		c.workflowOpts = append(c.workflowOpts, opts...)
+	}
+}
+
+// WithJSONLogs switches the runner's logging backend to line-delimited
+// JSON (one object per event with an ISO-8601 timestamp, level, message
+// and fields) instead of human-readable text. Logs always go to stderr
+// regardless of this setting, so stdout carries only the final results
+// payload the command-line tools print.
+func WithJSONLogs() Option {
+	return func(c *runnerConfig) {
+		c.jsonLogs = true
+	}
+}
+
+// WithLogLevel sets the minimum level the runner and the workflow engine
+// it drives will emit. Defaults to logging.Info.
+func WithLogLevel(level logging.Level) Option {
+	return func(c *runnerConfig) {
+		c.logLevel = level
+	}
+}
+
+func NewRunner(workDir string, opts ...Option) (*Runner, error) {
 	resolved, err := ResolveWorkDir(workDir)
 	if err != nil {
 		return nil, err
 	}
-	exec, err := workflow.NewExecutor(resolved, opts...)
+
+	cfg := &runnerConfig{logLevel: logging.Info}
+	for _, opt := range opts {
+		opt(cfg)
+	}
+
+	log := logging.New(logging.Options{Name: "service", Level: cfg.logLevel, JSON: cfg.jsonLogs})
+
+	workflowOpts := append([]workflow.Option{workflow.WithLogger(log.Named("executor"))}, cfg.workflowOpts...)
+	exec, err := workflow.NewExecutor(resolved, workflowOpts...)
 	if err != nil {
 		return nil, err
 	}
-	return &Runner{WorkDir: resolved, exec: exec}, nil
+
+	registry, err := NewRunRegistry(filepath.Join(resolved, ".cads-runs"))
+	if err != nil {
+		return nil, err
+	}
+
+	return &Runner{WorkDir: resolved, Log: log, Registry: registry, exec: exec}, nil
+}
+
+// Run executes the workflow synchronously and returns its results.
+// Cancelling ctx (e.g. via signal.NotifyContext) stops every step still
+// running through the same cads_run_fmu_cancel path a fail_fast step
+// uses on its siblings.
+func (r *Runner) Run(ctx context.Context, workflowPath string) (map[string]map[string]any, error) {
+	return r.exec.Run(workflowPath, workflow.WithExecutorContext(workflow.NewExecutorContext(ctx)))
+}
+
+// Plan resolves the workflow's dependency graph and step bindings without
+// invoking cgo, for --dry-run.
+func (r *Runner) Plan(workflowPath string) (*workflow.Plan, error) {
+	return r.exec.Plan(workflowPath)
 }
 
-// Run executes the workflow and returns its results.
-func (r *Runner) Run(workflowPath string) (map[string]map[string]any, error) {
-	return r.exec.Run(workflowPath)
+// StartRun registers a new run in the Registry and executes it in a
+// background goroutine, returning the run ID immediately. The run's
+// snapshot and step events are available through Registry until the
+// workflow finishes, and its final snapshot persists past that.
+//
+// The run's ExecutorContext is rooted in context.Background(), not the
+// HTTP request that triggered it: the request ends as soon as the run ID
+// is returned, long before the workflow does.
+func (r *Runner) StartRun(workflowPath string) string {
+	id := r.Registry.Start(workflowPath)
+
+	events := make(chan workflow.StepEvent, 32)
+	go func() {
+		for ev := range events {
+			r.Registry.Publish(id, ev)
+		}
+	}()
+
+	go func() {
+		defer close(events)
+		r.Registry.MarkRunning(id)
+		results, err := r.exec.Run(workflowPath, workflow.WithSubscriber(events))
+		r.Registry.Finish(id, results, err)
+	}()
+
+	return id
 }
 
 // ResolveWorkDir figures out the repository root when not provided.
@@ -0,0 +1,251 @@
+package service
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/norceresearch/cads-fmi-demo/orchestrator/service/workflow"
+)
+
+// RunState is the lifecycle state of an entire workflow run, as opposed
+// to workflow.StepState which tracks an individual step.
+type RunState string
+
+const (
+	RunPending   RunState = "pending"
+	RunRunning   RunState = "running"
+	RunCompleted RunState = "completed"
+	RunFailed    RunState = "failed"
+)
+
+// RunSnapshot is the point-in-time view of a run served by GET /runs/{id}.
+// Results holds whatever steps have completed so far; it is the final
+// results map once State is RunCompleted or RunFailed.
+type RunSnapshot struct {
+	ID         string                    `json:"id"`
+	Workflow   string                    `json:"workflow"`
+	State      RunState                  `json:"state"`
+	Results    map[string]map[string]any `json:"results,omitempty"`
+	Error      string                    `json:"error,omitempty"`
+	StartedAt  time.Time                 `json:"started_at"`
+	FinishedAt *time.Time                `json:"finished_at,omitempty"`
+}
+
+// RunRegistry tracks in-flight and historical workflow runs, persisting
+// each one's snapshot to disk so GET /runs/{id} keeps answering for past
+// runs across an orchestrator restart.
+type RunRegistry struct {
+	dir string
+
+	mu   sync.Mutex
+	runs map[string]*trackedRun
+	seq  int64
+}
+
+type trackedRun struct {
+	mu       sync.Mutex
+	snapshot RunSnapshot
+
+	subMu sync.Mutex
+	subs  map[chan<- workflow.StepEvent]struct{}
+}
+
+// NewRunRegistry creates a registry that persists snapshots under dir,
+// loading any snapshots already on disk from a previous process.
+func NewRunRegistry(dir string) (*RunRegistry, error) {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, fmt.Errorf("create run registry dir: %w", err)
+	}
+
+	r := &RunRegistry{dir: dir, runs: make(map[string]*trackedRun)}
+
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, fmt.Errorf("read run registry dir: %w", err)
+	}
+	for _, entry := range entries {
+		if entry.IsDir() || filepath.Ext(entry.Name()) != ".json" {
+			continue
+		}
+		data, err := os.ReadFile(filepath.Join(dir, entry.Name()))
+		if err != nil {
+			continue
+		}
+		var snap RunSnapshot
+		if err := json.Unmarshal(data, &snap); err != nil {
+			continue
+		}
+		run := &trackedRun{snapshot: snap, subs: make(map[chan<- workflow.StepEvent]struct{})}
+		if snap.State == RunPending || snap.State == RunRunning {
+			// The process that owned this run is gone: nothing will ever
+			// call Finish for it again, so leaving it Pending/Running
+			// would report it as perpetually in-progress and leave
+			// streamEvents subscribing to a run that can never publish
+			// or close. Reconcile it to a terminal state up front.
+			now := time.Now()
+			run.snapshot.State = RunFailed
+			run.snapshot.Error = "run was interrupted by an orchestrator restart"
+			run.snapshot.FinishedAt = &now
+			r.runs[snap.ID] = run
+			r.persist(run)
+			continue
+		}
+		r.runs[snap.ID] = run
+	}
+	return r, nil
+}
+
+// Start records a new run in the Pending state and returns its ID.
+func (r *RunRegistry) Start(workflowPath string) string {
+	r.mu.Lock()
+	r.seq++
+	id := fmt.Sprintf("run-%d-%d", time.Now().UnixNano(), r.seq)
+	run := &trackedRun{
+		snapshot: RunSnapshot{ID: id, Workflow: workflowPath, State: RunPending, StartedAt: time.Now()},
+		subs:     make(map[chan<- workflow.StepEvent]struct{}),
+	}
+	r.runs[id] = run
+	r.mu.Unlock()
+
+	r.persist(run)
+	return id
+}
+
+// Get returns the current snapshot for id.
+func (r *RunRegistry) Get(id string) (RunSnapshot, bool) {
+	r.mu.Lock()
+	run, ok := r.runs[id]
+	r.mu.Unlock()
+	if !ok {
+		return RunSnapshot{}, false
+	}
+	run.mu.Lock()
+	defer run.mu.Unlock()
+	return run.snapshot, true
+}
+
+// MarkRunning records that a run has left the queue and started
+// executing its workflow.
+func (r *RunRegistry) MarkRunning(id string) {
+	r.mu.Lock()
+	run := r.runs[id]
+	r.mu.Unlock()
+	if run == nil {
+		return
+	}
+	run.mu.Lock()
+	run.snapshot.State = RunRunning
+	run.mu.Unlock()
+	r.persist(run)
+}
+
+// Finish records a run's terminal outcome, persists it and closes out
+// every subscriber streaming its events.
+func (r *RunRegistry) Finish(id string, results map[string]map[string]any, runErr error) {
+	r.mu.Lock()
+	run := r.runs[id]
+	r.mu.Unlock()
+	if run == nil {
+		return
+	}
+
+	now := time.Now()
+	run.mu.Lock()
+	run.snapshot.Results = results
+	run.snapshot.FinishedAt = &now
+	if runErr != nil {
+		run.snapshot.State = RunFailed
+		run.snapshot.Error = runErr.Error()
+	} else {
+		run.snapshot.State = RunCompleted
+	}
+	run.mu.Unlock()
+	r.persist(run)
+
+	run.subMu.Lock()
+	for ch := range run.subs {
+		close(ch)
+	}
+	run.subs = make(map[chan<- workflow.StepEvent]struct{})
+	run.subMu.Unlock()
+}
+
+// Publish records a step event's partial results against the run's
+// snapshot and forwards it to every subscriber streaming this run. It is
+// a no-op once the run has finished, so a buffered event processed after
+// Finish can't clobber the authoritative final Results Finish recorded.
+func (r *RunRegistry) Publish(id string, ev workflow.StepEvent) {
+	r.mu.Lock()
+	run := r.runs[id]
+	r.mu.Unlock()
+	if run == nil {
+		return
+	}
+
+	run.mu.Lock()
+	if run.snapshot.State == RunCompleted || run.snapshot.State == RunFailed {
+		run.mu.Unlock()
+		return
+	}
+	run.snapshot.Results = ev.OutputsSoFar
+	run.mu.Unlock()
+
+	run.subMu.Lock()
+	defer run.subMu.Unlock()
+	for ch := range run.subs {
+		select {
+		case ch <- ev:
+		default:
+		}
+	}
+}
+
+// Subscribe streams StepEvents for a single run until the returned
+// function is called or the run finishes, whichever comes first. It
+// reports false if id is unknown, or if the run has already reached a
+// terminal state: Finish closes out subscribers exactly once, so a run
+// that is already RunCompleted/RunFailed (including one restored from
+// disk on startup, which never runs Finish again in this process) would
+// otherwise register a channel that is never closed. Callers should fall
+// back to Get for the run's final snapshot in that case.
+func (r *RunRegistry) Subscribe(id string, ch chan<- workflow.StepEvent) (unsubscribe func(), ok bool) {
+	r.mu.Lock()
+	run, ok := r.runs[id]
+	r.mu.Unlock()
+	if !ok {
+		return nil, false
+	}
+
+	run.mu.Lock()
+	terminal := run.snapshot.State == RunCompleted || run.snapshot.State == RunFailed
+	run.mu.Unlock()
+	if terminal {
+		return nil, false
+	}
+
+	run.subMu.Lock()
+	run.subs[ch] = struct{}{}
+	run.subMu.Unlock()
+
+	return func() {
+		run.subMu.Lock()
+		delete(run.subs, ch)
+		run.subMu.Unlock()
+	}, true
+}
+
+func (r *RunRegistry) persist(run *trackedRun) {
+	run.mu.Lock()
+	snap := run.snapshot
+	run.mu.Unlock()
+
+	data, err := json.MarshalIndent(snap, "", "  ")
+	if err != nil {
+		return
+	}
+	_ = os.WriteFile(filepath.Join(r.dir, snap.ID+".json"), data, 0o644)
+}
@@ -2,12 +2,24 @@ package service
 
 import (
 	"encoding/json"
-	"log"
+	"fmt"
 	"net/http"
+	"strings"
+
+	"github.com/norceresearch/cads-fmi-demo/orchestrator/logging"
+	"github.com/norceresearch/cads-fmi-demo/orchestrator/service/workflow"
 )
 
 type Server struct {
 	Runner *Runner
+	Log    logging.Logger
+}
+
+func (s *Server) log() logging.Logger {
+	if s.Log != nil {
+		return s.Log
+	}
+	return logging.NewNop()
 }
 
 type runRequest struct {
@@ -15,16 +27,26 @@ type runRequest struct {
 }
 
 type runResponse struct {
-	Workflow string                    `json:"workflow"`
-	Results  map[string]map[string]any `json:"results"`
+	ID       string `json:"id"`
+	Workflow string `json:"workflow"`
 }
 
+// ServeHTTP handles POST /run, GET /runs/{id} and GET /runs/{id}/events.
 func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
-	if r.Method != http.MethodPost || r.URL.Path != "/run" {
+	switch {
+	case r.Method == http.MethodPost && r.URL.Path == "/run":
+		s.handleRun(w, r)
+	case r.Method == http.MethodGet && strings.HasPrefix(r.URL.Path, "/runs/"):
+		s.handleRuns(w, r)
+	default:
 		http.NotFound(w, r)
-		return
 	}
+}
 
+// handleRun starts a workflow in the background and returns its run ID
+// immediately; callers poll GET /runs/{id} or stream GET /runs/{id}/events
+// for progress and the final result.
+func (s *Server) handleRun(w http.ResponseWriter, r *http.Request) {
 	var req runRequest
 	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
 		http.Error(w, "invalid JSON payload", http.StatusBadRequest)
@@ -35,15 +57,117 @@ func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	results, err := s.Runner.Run(req.Workflow)
-	if err != nil {
-		log.Printf("workflow %s failed: %v", req.Workflow, err)
+	id := s.Runner.StartRun(req.Workflow)
+	s.log().Info("run started", "workflow", req.Workflow, "run_id", id)
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusAccepted)
+	if err := json.NewEncoder(w).Encode(runResponse{ID: id, Workflow: req.Workflow}); err != nil {
 		http.Error(w, err.Error(), http.StatusInternalServerError)
+	}
+}
+
+func (s *Server) handleRuns(w http.ResponseWriter, r *http.Request) {
+	rest := strings.TrimPrefix(r.URL.Path, "/runs/")
+	id, sub, hasSub := strings.Cut(rest, "/")
+	if id == "" {
+		http.NotFound(w, r)
 		return
 	}
+	if hasSub {
+		if sub != "events" {
+			http.NotFound(w, r)
+			return
+		}
+		s.streamEvents(w, r, id)
+		return
+	}
+	s.getRun(w, r, id)
+}
 
+// getRun returns the current snapshot (state, partial results, and final
+// results once complete) for a single run.
+func (s *Server) getRun(w http.ResponseWriter, r *http.Request, id string) {
+	snapshot, ok := s.Runner.Registry.Get(id)
+	if !ok {
+		http.NotFound(w, r)
+		return
+	}
 	w.Header().Set("Content-Type", "application/json")
-	if err := json.NewEncoder(w).Encode(runResponse{Workflow: req.Workflow, Results: results}); err != nil {
+	if err := json.NewEncoder(w).Encode(snapshot); err != nil {
 		http.Error(w, err.Error(), http.StatusInternalServerError)
 	}
 }
+
+// streamEvents serves a run's StepEvents as Server-Sent Events until the
+// run finishes or the client disconnects. A run that is already terminal
+// when the client connects (most notably one restored from disk on
+// startup, which never runs again in this process) has no more StepEvents
+// coming; stream its final snapshot instead of subscribing and blocking
+// forever.
+func (s *Server) streamEvents(w http.ResponseWriter, r *http.Request, id string) {
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		http.Error(w, "streaming unsupported", http.StatusInternalServerError)
+		return
+	}
+
+	snapshot, ok := s.Runner.Registry.Get(id)
+	if !ok {
+		http.NotFound(w, r)
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("Connection", "keep-alive")
+	w.WriteHeader(http.StatusOK)
+
+	if snapshot.State == RunCompleted || snapshot.State == RunFailed {
+		s.writeSnapshotEvent(w, flusher, snapshot)
+		return
+	}
+
+	events := make(chan workflow.StepEvent, 32)
+	unsubscribe, ok := s.Runner.Registry.Subscribe(id, events)
+	if !ok {
+		// The run finished between the Get above and this Subscribe;
+		// fall back to its now-final snapshot instead of blocking.
+		if snapshot, ok := s.Runner.Registry.Get(id); ok {
+			s.writeSnapshotEvent(w, flusher, snapshot)
+		}
+		return
+	}
+	defer unsubscribe()
+
+	flusher.Flush()
+
+	for {
+		select {
+		case <-r.Context().Done():
+			return
+		case ev, open := <-events:
+			if !open {
+				return
+			}
+			data, err := json.Marshal(ev)
+			if err != nil {
+				continue
+			}
+			fmt.Fprintf(w, "event: state\ndata: %s\n\n", data)
+			flusher.Flush()
+		}
+	}
+}
+
+// writeSnapshotEvent emits a run's current snapshot as a single
+// Server-Sent Event, for clients that connect to a run that is already
+// (or becomes, mid-stream) terminal and so has no more StepEvents coming.
+func (s *Server) writeSnapshotEvent(w http.ResponseWriter, flusher http.Flusher, snapshot RunSnapshot) {
+	data, err := json.Marshal(snapshot)
+	if err != nil {
+		return
+	}
+	fmt.Fprintf(w, "event: snapshot\ndata: %s\n\n", data)
+	flusher.Flush()
+}
@@ -0,0 +1,210 @@
+package workflow
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/sourcegraph/jsonrpc2"
+
+	"github.com/norceresearch/cads-fmi-demo/orchestrator/internal/fmi"
+)
+
+// AgentCapabilities is what an agent reports when it registers with the
+// pool: enough for the scheduler to match a step's run_on labels against
+// it without having to ask the agent anything further.
+type AgentCapabilities struct {
+	OS       string            `json:"os"`
+	Arch     string            `json:"arch"`
+	FMILibs  []string          `json:"fmi_libs"`
+	MaxSlots int               `json:"max_slots"`
+	Labels   map[string]string `json:"labels"`
+}
+
+// runRPCArgs/runRPCReply are the JSON-RPC 2.0 payloads exchanged for the
+// "Run" method the agent serves.
+type runRPCArgs struct {
+	Config fmi.Config `json:"config"`
+}
+
+type runRPCReply struct {
+	Outputs map[string]any `json:"outputs"`
+	FMUErr  string         `json:"fmu_error,omitempty"`
+}
+
+const (
+	heartbeatInterval = 10 * time.Second
+	heartbeatTimeout  = 3 * heartbeatInterval
+)
+
+type pooledAgent struct {
+	id       string
+	conn     *jsonrpc2.Conn
+	caps     AgentCapabilities
+	mu       sync.Mutex
+	inFlight int
+	lastSeen time.Time
+}
+
+func (a *pooledAgent) matches(labels RunOn) bool {
+	for k, v := range labels {
+		if a.caps.Labels[k] != v {
+			return false
+		}
+	}
+	return true
+}
+
+func (a *pooledAgent) hasCapacity() bool {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	return a.caps.MaxSlots <= 0 || a.inFlight < a.caps.MaxSlots
+}
+
+// AgentPool tracks connected agents and picks one to run a labeled step.
+// Agents register themselves over a persistent JSON-RPC 2.0 connection and
+// are evicted once their heartbeat goes stale.
+type AgentPool struct {
+	mu     sync.Mutex
+	agents map[string]*pooledAgent
+}
+
+// NewAgentPool creates an empty pool.
+func NewAgentPool() *AgentPool {
+	return &AgentPool{agents: make(map[string]*pooledAgent)}
+}
+
+// Register adds an agent's connection and capabilities to the pool under
+// the given ID, replacing any agent already registered under it.
+func (p *AgentPool) Register(id string, conn *jsonrpc2.Conn, caps AgentCapabilities) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.agents[id] = &pooledAgent{id: id, conn: conn, caps: caps, lastSeen: time.Now()}
+}
+
+// Heartbeat refreshes the last-seen time for an agent so it isn't evicted.
+func (p *AgentPool) Heartbeat(id string) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if a, ok := p.agents[id]; ok {
+		a.lastSeen = time.Now()
+	}
+}
+
+// Unregister drops an agent, e.g. once its connection closes.
+func (p *AgentPool) Unregister(id string) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	delete(p.agents, id)
+}
+
+// EvictStale removes agents whose heartbeat is older than heartbeatTimeout
+// and returns how many were evicted. Callers run this on a ticker.
+func (p *AgentPool) EvictStale() int {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	evicted := 0
+	cutoff := time.Now().Add(-heartbeatTimeout)
+	for id, a := range p.agents {
+		if a.lastSeen.Before(cutoff) {
+			delete(p.agents, id)
+			evicted++
+		}
+	}
+	return evicted
+}
+
+// StartEvictionLoop periodically drops agents that have missed their
+// heartbeat, until ctx is cancelled. It should be run once per pool
+// alongside the HTTP server that accepts agent connections.
+func (p *AgentPool) StartEvictionLoop(ctx context.Context) {
+	ticker := time.NewTicker(heartbeatInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			p.EvictStale()
+		}
+	}
+}
+
+// pick returns an agent matching labels with spare capacity, excluding any
+// agent ID in exclude (used by the executor to avoid re-dispatching a step
+// to the agent that just failed it).
+func (p *AgentPool) pick(labels RunOn, exclude map[string]bool) (*pooledAgent, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	for id, a := range p.agents {
+		if exclude[id] {
+			continue
+		}
+		if a.matches(labels) && a.hasCapacity() {
+			return a, nil
+		}
+	}
+	return nil, fmt.Errorf("%w: no agent available matching %v", ErrAgentTransport, labels)
+}
+
+// RemoteDispatcher hands a step's FMU execution to whichever pooled agent
+// matches the step's run_on labels.
+type RemoteDispatcher struct {
+	Pool    *AgentPool
+	exclude map[string]bool
+}
+
+// NewRemoteDispatcher dispatches through the given pool.
+func NewRemoteDispatcher(pool *AgentPool) *RemoteDispatcher {
+	return &RemoteDispatcher{Pool: pool}
+}
+
+func (d *RemoteDispatcher) Dispatch(ctx context.Context, step workflowStep, cfg fmi.Config) (map[string]any, error) {
+	agent, err := d.Pool.pick(step.RunOn, d.exclude)
+	if err != nil {
+		return nil, err
+	}
+
+	agent.mu.Lock()
+	agent.inFlight++
+	agent.mu.Unlock()
+	defer func() {
+		agent.mu.Lock()
+		agent.inFlight--
+		agent.mu.Unlock()
+	}()
+
+	var reply runRPCReply
+	if err := agent.conn.Call(ctx, "Run", runRPCArgs{Config: cfg}, &reply); err != nil {
+		return nil, &transportError{agentID: agent.id, cause: err}
+	}
+	if reply.FMUErr != "" {
+		return nil, fmt.Errorf("fmi runner: %s", reply.FMUErr)
+	}
+	return reply.Outputs, nil
+}
+
+// excluding returns a copy of the dispatcher that avoids the given agent,
+// used by the executor's retry loop after a transport failure.
+func (d *RemoteDispatcher) excluding(agentID string) *RemoteDispatcher {
+	next := make(map[string]bool, len(d.exclude)+1)
+	for id := range d.exclude {
+		next[id] = true
+	}
+	next[agentID] = true
+	return &RemoteDispatcher{Pool: d.Pool, exclude: next}
+}
+
+// transportError identifies which agent a failed dispatch went to, so the
+// executor's retry loop can exclude it on the next attempt.
+type transportError struct {
+	agentID string
+	cause   error
+}
+
+func (e *transportError) Error() string {
+	return fmt.Sprintf("agent %s: %v", e.agentID, e.cause)
+}
+
+func (e *transportError) Unwrap() error { return ErrAgentTransport }
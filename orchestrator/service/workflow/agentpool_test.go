@@ -0,0 +1,39 @@
+package workflow
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/norceresearch/cads-fmi-demo/orchestrator/internal/fmi"
+	"github.com/norceresearch/cads-fmi-demo/orchestrator/logging"
+)
+
+// TestRunRPCArgsJSONRoundTrip guards against fmi.Config.Logger leaking
+// into the wire payload RemoteDispatcher sends: agent.conn.Call marshals
+// runRPCArgs with the real encoding/json, and a non-nil Logger interface
+// value fails to unmarshal back into the agent's Config (its concrete
+// type has only unexported fields), which would break every
+// remote-dispatched step.
+func TestRunRPCArgsJSONRoundTrip(t *testing.T) {
+	args := runRPCArgs{Config: fmi.Config{
+		FMUPath: "model.fmu",
+		Outputs: []string{"y"},
+		Logger:  logging.NewNop(),
+	}}
+
+	data, err := json.Marshal(args)
+	if err != nil {
+		t.Fatalf("marshal: %v", err)
+	}
+
+	var got runRPCArgs
+	if err := json.Unmarshal(data, &got); err != nil {
+		t.Fatalf("unmarshal: %v", err)
+	}
+	if got.Config.FMUPath != args.Config.FMUPath {
+		t.Errorf("FMUPath: got %q, want %q", got.Config.FMUPath, args.Config.FMUPath)
+	}
+	if got.Config.Logger != nil {
+		t.Errorf("Logger: got %v, want nil (Logger must not survive the wire)", got.Config.Logger)
+	}
+}
@@ -0,0 +1,186 @@
+package workflow
+
+import (
+	"fmt"
+	"strings"
+)
+
+// resolveDependencies determines each step's prerequisites. If no step in
+// the file declares depends_on, every step depends on the one immediately
+// before it in the file, preserving the strictly-sequential behavior the
+// executor had before depends_on existed. Once any step declares
+// depends_on, every step's prerequisites come only from its own
+// depends_on list; a step with none is ready immediately.
+func resolveDependencies(steps []workflowStep) map[string][]string {
+	deps := make(map[string][]string, len(steps))
+
+	declared := false
+	for _, step := range steps {
+		if len(step.DependsOn) > 0 {
+			declared = true
+			break
+		}
+	}
+	if !declared {
+		for i, step := range steps {
+			if i == 0 {
+				deps[step.Name] = nil
+				continue
+			}
+			deps[step.Name] = []string{steps[i-1].Name}
+		}
+		return deps
+	}
+
+	for _, step := range steps {
+		deps[step.Name] = append([]string(nil), step.DependsOn...)
+	}
+	return deps
+}
+
+// topologicalOrder returns the steps in an order where every step follows
+// all of its dependencies, breaking ties by original file order so
+// dry-run output is stable. It fails if the dependency graph has a cycle.
+func topologicalOrder(steps []workflowStep, deps map[string][]string) ([]string, error) {
+	fileIndex := make(map[string]int, len(steps))
+	indegree := make(map[string]int, len(steps))
+	dependents := make(map[string][]string, len(steps))
+	for i, step := range steps {
+		fileIndex[step.Name] = i
+		indegree[step.Name] = 0
+	}
+	for name, ds := range deps {
+		for _, dep := range ds {
+			dependents[dep] = append(dependents[dep], name)
+			indegree[name]++
+		}
+	}
+
+	ready := make([]string, 0, len(steps))
+	for _, step := range steps {
+		if indegree[step.Name] == 0 {
+			ready = append(ready, step.Name)
+		}
+	}
+
+	order := make([]string, 0, len(steps))
+	for len(ready) > 0 {
+		best := 0
+		for i := 1; i < len(ready); i++ {
+			if fileIndex[ready[i]] < fileIndex[ready[best]] {
+				best = i
+			}
+		}
+		name := ready[best]
+		ready = append(ready[:best], ready[best+1:]...)
+		order = append(order, name)
+
+		for _, dependent := range dependents[name] {
+			indegree[dependent]--
+			if indegree[dependent] == 0 {
+				ready = append(ready, dependent)
+			}
+		}
+	}
+
+	if len(order) < len(steps) {
+		return nil, fmt.Errorf("dependency cycle detected: %s", strings.Join(findCycle(steps, deps), " -> "))
+	}
+	return order, nil
+}
+
+// findCycle returns the step names forming a dependency cycle. Callers
+// only invoke it once topologicalOrder has already determined a cycle
+// exists, so it always finds one.
+func findCycle(steps []workflowStep, deps map[string][]string) []string {
+	const (
+		white = iota
+		gray
+		black
+	)
+	color := make(map[string]int, len(steps))
+	var path []string
+	var cycle []string
+
+	var visit func(name string) bool
+	visit = func(name string) bool {
+		color[name] = gray
+		path = append(path, name)
+		for _, dep := range deps[name] {
+			switch color[dep] {
+			case gray:
+				start := 0
+				for i, n := range path {
+					if n == dep {
+						start = i
+						break
+					}
+				}
+				cycle = append(append([]string(nil), path[start:]...), dep)
+				return true
+			case white:
+				if visit(dep) {
+					return true
+				}
+			}
+		}
+		path = path[:len(path)-1]
+		color[name] = black
+		return false
+	}
+
+	for _, step := range steps {
+		if color[step.Name] == white && visit(step.Name) {
+			return cycle
+		}
+	}
+	return nil
+}
+
+// ancestors returns every step name reachable by following dependency
+// edges from name, i.e. its transitive prerequisites. It assumes deps is
+// acyclic; callers run it only after topologicalOrder has confirmed that.
+func ancestors(name string, deps map[string][]string, memo map[string]map[string]bool) map[string]bool {
+	if cached, ok := memo[name]; ok {
+		return cached
+	}
+	result := make(map[string]bool)
+	memo[name] = result
+	for _, dep := range deps[name] {
+		result[dep] = true
+		for a := range ancestors(dep, deps, memo) {
+			result[a] = true
+		}
+	}
+	return result
+}
+
+// validateStartFrom fails fast if any step's start_from references a step
+// that isn't among its transitive dependencies. Under concurrent
+// execution a step only waits on its declared depends_on, so reading
+// another step's output without depending on it is a race, not a bug
+// that happens to work.
+func validateStartFrom(steps []workflowStep, deps map[string][]string) error {
+	byName := make(map[string]bool, len(steps))
+	for _, step := range steps {
+		byName[step.Name] = true
+	}
+
+	memo := make(map[string]map[string]bool, len(steps))
+	for _, step := range steps {
+		anc := ancestors(step.Name, deps, memo)
+		for target, ref := range step.StartFrom {
+			refStep, _, ok := strings.Cut(ref, ".")
+			if !ok || refStep == "" {
+				continue // reported by buildStartValues once the step actually runs
+			}
+			if !byName[refStep] {
+				return fmt.Errorf("step %s start_from[%s] references unknown step %s", step.Name, target, refStep)
+			}
+			if !anc[refStep] {
+				return fmt.Errorf("step %s start_from[%s] references step %s, which is not in its depends_on (transitively); the scheduler cannot guarantee it has run yet", step.Name, target, refStep)
+			}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,87 @@
+package workflow
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestResolveDependencies(t *testing.T) {
+	t.Run("defaults to file order when nothing declares depends_on", func(t *testing.T) {
+		steps := []workflowStep{{Name: "a"}, {Name: "b"}, {Name: "c"}}
+		deps := resolveDependencies(steps)
+		if got := deps["a"]; got != nil {
+			t.Errorf("a: want no deps, got %v", got)
+		}
+		if got := deps["b"]; len(got) != 1 || got[0] != "a" {
+			t.Errorf("b: want [a], got %v", got)
+		}
+		if got := deps["c"]; len(got) != 1 || got[0] != "b" {
+			t.Errorf("c: want [b], got %v", got)
+		}
+	})
+
+	t.Run("uses only declared depends_on once any step declares one", func(t *testing.T) {
+		steps := []workflowStep{
+			{Name: "a"},
+			{Name: "b", DependsOn: []string{"a"}},
+			{Name: "c"},
+		}
+		deps := resolveDependencies(steps)
+		if got := deps["c"]; got != nil {
+			t.Errorf("c: want no deps (file-order fallback only applies with zero declarations), got %v", got)
+		}
+	})
+}
+
+func TestTopologicalOrder_DetectsCycle(t *testing.T) {
+	steps := []workflowStep{{Name: "a"}, {Name: "b"}, {Name: "c"}}
+	deps := map[string][]string{
+		"a": {"c"},
+		"b": {"a"},
+		"c": {"b"},
+	}
+	_, err := topologicalOrder(steps, deps)
+	if err == nil {
+		t.Fatal("expected a cycle error")
+	}
+	if !strings.Contains(err.Error(), "cycle") {
+		t.Fatalf("expected error to mention the cycle, got %q", err)
+	}
+}
+
+func TestTopologicalOrder_OrdersByDependencyThenFilePosition(t *testing.T) {
+	steps := []workflowStep{{Name: "a"}, {Name: "b"}, {Name: "c"}}
+	deps := map[string][]string{
+		"a": {"c"},
+		"b": nil,
+		"c": nil,
+	}
+	order, err := topologicalOrder(steps, deps)
+	if err != nil {
+		t.Fatalf("topologicalOrder: %v", err)
+	}
+	// b and c have no deps and tie on readiness; c must precede a either
+	// way since a depends on it. File order (b before c) breaks the tie.
+	indexOf := make(map[string]int, len(order))
+	for i, name := range order {
+		indexOf[name] = i
+	}
+	if indexOf["c"] >= indexOf["a"] {
+		t.Fatalf("c must come before a, got order %v", order)
+	}
+	if indexOf["b"] >= indexOf["c"] {
+		t.Fatalf("b ties with c on readiness and should win on file order, got %v", order)
+	}
+}
+
+func TestValidateStartFrom_RejectsReferenceOutsideDependsOn(t *testing.T) {
+	steps := []workflowStep{
+		{Name: "a"},
+		{Name: "b", StartFrom: map[string]string{"x": "a.y"}},
+	}
+	deps := map[string][]string{"a": nil, "b": nil}
+	err := validateStartFrom(steps, deps)
+	if err == nil {
+		t.Fatal("expected an error: b does not depend_on a")
+	}
+}
@@ -0,0 +1,29 @@
+package workflow
+
+import (
+	"context"
+	"errors"
+
+	"github.com/norceresearch/cads-fmi-demo/orchestrator/internal/fmi"
+)
+
+// ErrAgentTransport marks a dispatch failure that originated from the
+// transport layer (a dead connection, a timeout, an agent that vanished)
+// rather than from the FMU itself. The executor retries these on a
+// different agent; it never retries an error the FMU reported on purpose.
+var ErrAgentTransport = errors.New("workflow: agent transport error")
+
+// Dispatcher runs a single step's FMU configuration and returns its
+// outputs. LocalDispatcher runs in-process through the cgo fmi bridge;
+// RemoteDispatcher hands the work to an agent in the pool.
+type Dispatcher interface {
+	Dispatch(ctx context.Context, step workflowStep, cfg fmi.Config) (map[string]any, error)
+}
+
+// LocalDispatcher runs every step in-process via fmi.Run. It is the
+// executor's default and requires no agent pool.
+type LocalDispatcher struct{}
+
+func (LocalDispatcher) Dispatch(ctx context.Context, step workflowStep, cfg fmi.Config) (map[string]any, error) {
+	return fmi.Run(ctx, cfg)
+}
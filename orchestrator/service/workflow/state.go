@@ -0,0 +1,34 @@
+package workflow
+
+import "time"
+
+// StepState is a step's position in its lifecycle, inspired by Nomad's
+// AllocRunner task states: a step moves forward through Pending, Received
+// and Starting before settling on Running and finally one of the three
+// terminal states.
+type StepState string
+
+const (
+	StepPending   StepState = "pending"
+	StepReceived  StepState = "received"
+	StepStarting  StepState = "starting"
+	StepRunning   StepState = "running"
+	StepCompleted StepState = "completed"
+	StepFailed    StepState = "failed"
+	StepSkipped   StepState = "skipped"
+)
+
+// StepEvent reports a single step's state transition. Subscribers receive
+// one of these per transition; OutputsSoFar is the accumulated result map
+// for every step that has completed in the run so far, so a client that
+// only sees the latest event still has the full picture.
+type StepEvent struct {
+	Workflow     string                    `json:"workflow"`
+	Step         string                    `json:"step"`
+	StepIndex    int                       `json:"step_index"`
+	State        StepState                 `json:"state"`
+	OutputsSoFar map[string]map[string]any `json:"outputs_so_far,omitempty"`
+	Error        string                    `json:"error,omitempty"`
+	ElapsedMs    int64                     `json:"elapsed_ms"`
+	Time         time.Time                 `json:"time"`
+}
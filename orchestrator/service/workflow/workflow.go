@@ -1,32 +1,97 @@
 package workflow
 
 import (
+	"context"
 	"encoding/json"
 	"errors"
 	"fmt"
 	"os"
 	"path/filepath"
+	"runtime"
 	"sort"
 	"strings"
+	"sync"
+	"time"
 
 	"gopkg.in/yaml.v3"
 
-	"github.com/norceresearch/cads-fmi-demo/orchestrator/service/internal/fmi"
+	"github.com/norceresearch/cads-fmi-demo/orchestrator/internal/fmi"
+	"github.com/norceresearch/cads-fmi-demo/orchestrator/logging"
 )
 
+// RunOn is the set of agent labels a step requires, e.g. {"os": "linux",
+// "has": "fmilib"}. A step with no run_on entries always runs locally.
+type RunOn map[string]string
+
+// defaultReceivedDelay is how long a step waits before its Running
+// transition is reported to subscribers. Steps that reach a terminal
+// state before the delay elapses never get a Running event at all, so a
+// workflow of fast steps doesn't flood subscribers with a transition
+// nobody had time to observe.
+const defaultReceivedDelay = 150 * time.Millisecond
+
 // Executor runs workflow YAML definitions directly against FMUs via FMIL.
 type Executor struct {
-	root   string
-	logger func(string, ...any)
+	root          string
+	log           logging.Logger
+	dispatcher    Dispatcher
+	maxRetries    int
+	retryWait     time.Duration
+	receivedDelay time.Duration
+	maxConcurrent int
+
+	subMu sync.Mutex
+	subs  map[chan<- StepEvent]struct{}
 }
 
 // Option configures the executor.
 type Option func(*Executor)
 
-// WithLogger installs a printf-style logger for workflow progress.
-func WithLogger(logger func(string, ...any)) Option {
+// WithLogger installs the logger workflow progress is reported through.
+// The executor attaches workflow, step, fmu and step_index fields to it
+// automatically; callers should not pre-attach those themselves.
+func WithLogger(log logging.Logger) Option {
+	return func(e *Executor) {
+		e.log = log
+	}
+}
+
+// WithDispatcher overrides how steps are executed. The default is
+// LocalDispatcher, which runs every step in-process through the cgo fmi
+// bridge; pass a RemoteDispatcher to hand steps with run_on labels off to
+// an agent pool.
+func WithDispatcher(d Dispatcher) Option {
+	return func(e *Executor) {
+		e.dispatcher = d
+	}
+}
+
+// WithRetry sets how many times a step is redispatched to a different
+// agent after a transport error, and how long to wait between attempts.
+// It has no effect on errors the FMU itself reports. The default is no
+// retries.
+func WithRetry(maxRetries int, wait time.Duration) Option {
+	return func(e *Executor) {
+		e.maxRetries = maxRetries
+		e.retryWait = wait
+	}
+}
+
+// WithReceivedDelay overrides how long a step waits before its Running
+// transition is reported to subscribers. The default is
+// defaultReceivedDelay.
+func WithReceivedDelay(d time.Duration) Option {
 	return func(e *Executor) {
-		e.logger = logger
+		e.receivedDelay = d
+	}
+}
+
+// WithMaxConcurrency bounds how many steps run at once. Steps whose
+// depends_on edges are satisfied still queue for a free slot beyond this
+// limit. The default is runtime.NumCPU().
+func WithMaxConcurrency(n int) Option {
+	return func(e *Executor) {
+		e.maxConcurrent = n
 	}
 }
 
@@ -35,15 +100,125 @@ func NewExecutor(repoRoot string, opts ...Option) (*Executor, error) {
 	if repoRoot == "" {
 		return nil, errors.New("workflow executor requires a repository root")
 	}
-	e := &Executor{root: repoRoot}
+	e := &Executor{
+		root:          repoRoot,
+		dispatcher:    LocalDispatcher{},
+		log:           logging.NewNop(),
+		receivedDelay: defaultReceivedDelay,
+		maxConcurrent: runtime.NumCPU(),
+		subs:          make(map[chan<- StepEvent]struct{}),
+	}
 	for _, opt := range opts {
 		opt(e)
 	}
+	if e.maxConcurrent < 1 {
+		e.maxConcurrent = 1
+	}
 	return e, nil
 }
 
-// Run executes a workflow file (relative to repo root unless absolute).
-func (e *Executor) Run(workflowPath string) (map[string]map[string]any, error) {
+// Subscribe registers ch to receive a StepEvent for every state transition
+// of every run this executor performs, until the returned function is
+// called. Publishing never blocks on a slow subscriber: an event that
+// can't be delivered immediately is dropped for that subscriber.
+func (e *Executor) Subscribe(ch chan<- StepEvent) func() {
+	e.subMu.Lock()
+	e.subs[ch] = struct{}{}
+	e.subMu.Unlock()
+
+	return func() {
+		e.subMu.Lock()
+		delete(e.subs, ch)
+		e.subMu.Unlock()
+	}
+}
+
+func (e *Executor) publish(ev StepEvent, extra ...chan<- StepEvent) {
+	ev.Time = time.Now()
+
+	e.subMu.Lock()
+	targets := make([]chan<- StepEvent, 0, len(e.subs)+len(extra))
+	for ch := range e.subs {
+		targets = append(targets, ch)
+	}
+	e.subMu.Unlock()
+	targets = append(targets, extra...)
+
+	for _, ch := range targets {
+		select {
+		case ch <- ev:
+		default:
+		}
+	}
+}
+
+// RunOption configures a single Run call, as opposed to Option which
+// configures the executor for its lifetime.
+type RunOption func(*runConfig)
+
+type runConfig struct {
+	subscriber chan<- StepEvent
+	execCtx    *ExecutorContext
+}
+
+// WithSubscriber additionally routes this run's StepEvents to ch, on top
+// of whatever subscribers are registered via Executor.Subscribe. Used by
+// callers that want to follow a single run (e.g. a RunRegistry entry)
+// without sifting through every run's events.
+func WithSubscriber(ch chan<- StepEvent) RunOption {
+	return func(c *runConfig) {
+		c.subscriber = ch
+	}
+}
+
+// WithExecutorContext roots every step's context in ec instead of
+// context.Background(). Cancelling ec (directly, or because its parent
+// was cancelled) cancels every step still running or about to start.
+func WithExecutorContext(ec *ExecutorContext) RunOption {
+	return func(c *runConfig) {
+		c.execCtx = ec
+	}
+}
+
+// ExecutorContext is the root context a single Run call derives every
+// step's context from. A step whose YAML sets fail_fast cancels it on
+// failure, which in turn cancels every other step already running or
+// still waiting on a dependency, via the cads_run_fmu_cancel path in
+// fmi.Run.
+type ExecutorContext struct {
+	ctx    context.Context
+	cancel context.CancelCauseFunc
+}
+
+// NewExecutorContext derives a cancellable ExecutorContext from parent.
+func NewExecutorContext(parent context.Context) *ExecutorContext {
+	ctx, cancel := context.WithCancelCause(parent)
+	return &ExecutorContext{ctx: ctx, cancel: cancel}
+}
+
+// Cancel cancels every step context derived from ec. Only the first call
+// determines the cause later steps observe via context.Cause.
+func (ec *ExecutorContext) Cancel(cause error) {
+	ec.cancel(cause)
+}
+
+// parsed is the validated, dependency-resolved form of a workflow file,
+// shared by Run and Plan so both see identical validation.
+type parsed struct {
+	absPath  string
+	wfName   string
+	doc      workflowFile
+	deps     map[string][]string
+	order    []string
+	fmuPaths map[string]string
+}
+
+// parseAndValidate reads and parses workflowPath, resolves its dependency
+// graph (falling back to file order when no step declares depends_on),
+// and fails fast on anything that would otherwise only surface mid-run:
+// duplicate or missing names, missing FMUs, dependency cycles, and
+// start_from references that aren't guaranteed to have completed.
+func (e *Executor) parseAndValidate(workflowPath string) (*parsed, error) {
 	absPath := workflowPath
 	if !filepath.IsAbs(absPath) {
 		absPath = filepath.Join(e.root, workflowPath)
@@ -61,17 +236,24 @@ func (e *Executor) Run(workflowPath string) (map[string]map[string]any, error) {
 		return nil, fmt.Errorf("workflow %s does not define any steps", absPath)
 	}
 
-	results := make(map[string]map[string]any, len(doc.Steps))
+	names := make(map[string]bool, len(doc.Steps))
+	fmuPaths := make(map[string]string, len(doc.Steps))
 	for _, step := range doc.Steps {
 		if step.Name == "" {
 			return nil, fmt.Errorf("workflow %s contains a step without name", absPath)
 		}
-		if _, exists := results[step.Name]; exists {
+		if names[step.Name] {
 			return nil, fmt.Errorf("workflow step %s defined multiple times", step.Name)
 		}
+		names[step.Name] = true
 		if step.FMU == "" {
 			return nil, fmt.Errorf("step %s is missing its fmu path", step.Name)
 		}
+		if step.Timeout != "" {
+			if _, err := time.ParseDuration(step.Timeout); err != nil {
+				return nil, fmt.Errorf("step %s has invalid timeout %q: %w", step.Name, step.Timeout, err)
+			}
+		}
 
 		fmuPath := step.FMU
 		if !filepath.IsAbs(fmuPath) {
@@ -80,47 +262,375 @@ func (e *Executor) Run(workflowPath string) (map[string]map[string]any, error) {
 		if _, err := os.Stat(fmuPath); err != nil {
 			return nil, fmt.Errorf("step %s references missing FMU %s: %w", step.Name, fmuPath, err)
 		}
+		fmuPaths[step.Name] = fmuPath
+	}
+	for _, step := range doc.Steps {
+		for _, dep := range step.DependsOn {
+			if !names[dep] {
+				return nil, fmt.Errorf("step %s depends_on unknown step %s", step.Name, dep)
+			}
+		}
+	}
 
-		startVals, err := e.buildStartValues(step, results)
+	deps := resolveDependencies(doc.Steps)
+	order, err := topologicalOrder(doc.Steps, deps)
+	if err != nil {
+		return nil, fmt.Errorf("workflow %s: %w", absPath, err)
+	}
+	if err := validateStartFrom(doc.Steps, deps); err != nil {
+		return nil, fmt.Errorf("workflow %s: %w", absPath, err)
+	}
+
+	return &parsed{
+		absPath:  absPath,
+		wfName:   filepath.Base(absPath),
+		doc:      doc,
+		deps:     deps,
+		order:    order,
+		fmuPaths: fmuPaths,
+	}, nil
+}
+
+// PlannedStep is one step's position in the resolved schedule, as
+// reported by Plan without invoking cgo.
+type PlannedStep struct {
+	Name        string            `json:"name"`
+	StepIndex   int               `json:"step_index"`
+	DependsOn   []string          `json:"depends_on,omitempty"`
+	StartValues map[string]string `json:"start_values,omitempty"`
+	StartFrom   map[string]string `json:"start_from,omitempty"`
+}
+
+// Plan is the resolved topological order and bindings --dry-run prints.
+type Plan struct {
+	Workflow string        `json:"workflow"`
+	Steps    []PlannedStep `json:"steps"`
+}
+
+// Plan resolves workflowPath's dependency graph and the start_values /
+// start_from bindings each step would receive, without invoking cgo. It
+// runs exactly the same validation Run does, so a plan that succeeds
+// means Run won't fail for the same reasons.
+func (e *Executor) Plan(workflowPath string) (*Plan, error) {
+	p, err := e.parseAndValidate(workflowPath)
+	if err != nil {
+		return nil, err
+	}
+
+	stepByName := make(map[string]workflowStep, len(p.doc.Steps))
+	indexByName := make(map[string]int, len(p.doc.Steps))
+	for i, step := range p.doc.Steps {
+		stepByName[step.Name] = step
+		indexByName[step.Name] = i
+	}
+
+	plan := &Plan{Workflow: p.wfName, Steps: make([]PlannedStep, 0, len(p.order))}
+	for _, name := range p.order {
+		step := stepByName[name]
+		literal, err := encodeLiteralStartValues(step)
 		if err != nil {
-			return nil, fmt.Errorf("step %s start values invalid: %w", step.Name, err)
+			return nil, fmt.Errorf("step %s: %w", name, err)
 		}
+		plan.Steps = append(plan.Steps, PlannedStep{
+			Name:        name,
+			StepIndex:   indexByName[name],
+			DependsOn:   p.deps[name],
+			StartValues: literal,
+			StartFrom:   step.StartFrom,
+		})
+	}
+	return plan, nil
+}
 
-		cfg := fmi.Config{
-			FMUPath:     fmuPath,
-			StartValues: startVals,
-			Outputs:     step.Outputs,
-		}
-		if step.StartTime != nil {
-			cfg.StartTime = step.StartTime
-		}
-		if step.StopTime != nil {
-			cfg.StopTime = step.StopTime
-		}
-		if step.StepSize != nil {
-			cfg.StepSize = step.StepSize
-		}
+// Run executes a workflow file (relative to repo root unless absolute).
+// Steps whose dependencies are satisfied run concurrently, bounded by
+// WithMaxConcurrency; a step only starts once every step named in its
+// depends_on (or, for workflows with no depends_on at all, the step
+// before it in the file) has completed. The returned results map holds
+// every step that completed successfully even when err is non-nil: a
+// failed step only stops its own dependents (and, with fail_fast, every
+// other step), not the results already recorded by independent steps
+// that finished first.
+func (e *Executor) Run(workflowPath string, opts ...RunOption) (map[string]map[string]any, error) {
+	var cfg runConfig
+	for _, opt := range opts {
+		opt(&cfg)
+	}
+	extra := make([]chan<- StepEvent, 0, 1)
+	if cfg.subscriber != nil {
+		extra = append(extra, cfg.subscriber)
+	}
+	execCtx := cfg.execCtx
+	if execCtx == nil {
+		execCtx = NewExecutorContext(context.Background())
+	}
 
-		result, err := fmi.Run(cfg)
-		if err != nil {
-			return nil, fmt.Errorf("step %s failed: %w", step.Name, err)
+	p, err := e.parseAndValidate(workflowPath)
+	if err != nil {
+		return nil, err
+	}
+	wfLog := e.log.With("workflow", p.wfName)
+
+	var resultsMu sync.RWMutex
+	results := make(map[string]map[string]any, len(p.doc.Steps))
+
+	var failedMu sync.Mutex
+	failed := make(map[string]bool, len(p.doc.Steps))
+
+	done := make(map[string]chan struct{}, len(p.doc.Steps))
+	for _, step := range p.doc.Steps {
+		done[step.Name] = make(chan struct{})
+	}
+
+	// abort is closed only by a fail_fast step's failure: it cancels
+	// every other step still waiting on a dependency or a concurrency
+	// slot, mirroring execCtx.Cancel below for steps already running. A
+	// non-fail_fast failure must not touch it — dependents already skip
+	// via the failed[dep] check a few lines down, and steps on an
+	// unrelated branch of the DAG are meant to keep running.
+	abort := make(chan struct{})
+	var abortOnce sync.Once
+	abortAll := func() {
+		abortOnce.Do(func() { close(abort) })
+	}
+
+	var firstErrMu sync.Mutex
+	var firstErr error
+	recordErr := func(err error) {
+		firstErrMu.Lock()
+		if firstErr == nil {
+			firstErr = err
 		}
+		firstErrMu.Unlock()
+	}
+
+	sem := make(chan struct{}, e.maxConcurrent)
+
+	var wg sync.WaitGroup
+	for stepIndex, step := range p.doc.Steps {
+		stepIndex, step := stepIndex, step
+		stepDeps := p.deps[step.Name]
+		fmuPath := p.fmuPaths[step.Name]
 
-		results[step.Name] = result
-		if step.ResultPath != "" {
-			if err := writeResultFile(e.resolvePath(step.ResultPath), result); err != nil {
-				return nil, fmt.Errorf("write result for step %s: %w", step.Name, err)
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			defer close(done[step.Name])
+
+			outputsSoFar := func() map[string]map[string]any {
+				resultsMu.RLock()
+				defer resultsMu.RUnlock()
+				return snapshotResults(results)
+			}
+
+			skip := func() {
+				failedMu.Lock()
+				failed[step.Name] = true
+				failedMu.Unlock()
+				e.publish(StepEvent{Workflow: p.wfName, Step: step.Name, StepIndex: stepIndex, State: StepSkipped, OutputsSoFar: outputsSoFar()}, extra...)
+			}
+
+			e.publish(StepEvent{Workflow: p.wfName, Step: step.Name, StepIndex: stepIndex, State: StepPending, OutputsSoFar: outputsSoFar()}, extra...)
+
+			waitChans := make([]<-chan struct{}, len(stepDeps))
+			for i, dep := range stepDeps {
+				waitChans[i] = done[dep]
+			}
+			if aborted := waitForAll(waitChans, abort); aborted {
+				skip()
+				return
+			}
+
+			failedMu.Lock()
+			depFailed := false
+			for _, dep := range stepDeps {
+				if failed[dep] {
+					depFailed = true
+					break
+				}
+			}
+			failedMu.Unlock()
+			if depFailed {
+				skip()
+				return
+			}
+
+			// Dependencies are satisfied; the step is handed to the
+			// scheduler and now waits only for a free concurrency slot.
+			e.publish(StepEvent{Workflow: p.wfName, Step: step.Name, StepIndex: stepIndex, State: StepReceived, OutputsSoFar: outputsSoFar()}, extra...)
+
+			select {
+			case <-abort:
+				skip()
+				return
+			case sem <- struct{}{}:
+			}
+			defer func() { <-sem }()
+
+			// The step now holds a concurrency slot and is preparing to
+			// dispatch.
+			e.publish(StepEvent{Workflow: p.wfName, Step: step.Name, StepIndex: stepIndex, State: StepStarting, OutputsSoFar: outputsSoFar()}, extra...)
+
+			resultsMu.RLock()
+			startVals, err := e.buildStartValues(step, results)
+			resultsMu.RUnlock()
+			if err != nil {
+				recordErr(fmt.Errorf("step %s start values invalid: %w", step.Name, err))
+				skip()
+				return
+			}
+
+			fcfg := fmi.Config{
+				FMUPath:     fmuPath,
+				StartValues: startVals,
+				Outputs:     step.Outputs,
+			}
+			if step.StartTime != nil {
+				fcfg.StartTime = step.StartTime
+			}
+			if step.StopTime != nil {
+				fcfg.StopTime = step.StopTime
 			}
+			if step.StepSize != nil {
+				fcfg.StepSize = step.StepSize
+			}
+
+			stepLog := wfLog.With("step", step.Name, "fmu", step.FMU, "step_index", stepIndex)
+			fcfg.Logger = stepLog
+
+			event := func(state StepState, elapsed time.Duration, stepErr error) StepEvent {
+				resultsMu.RLock()
+				outputs := snapshotResults(results)
+				resultsMu.RUnlock()
+				ev := StepEvent{
+					Workflow:     p.wfName,
+					Step:         step.Name,
+					StepIndex:    stepIndex,
+					State:        state,
+					OutputsSoFar: outputs,
+					ElapsedMs:    elapsed.Milliseconds(),
+				}
+				if stepErr != nil {
+					ev.Error = stepErr.Error()
+				}
+				return ev
+			}
+
+			start := time.Now()
+
+			debounce := time.AfterFunc(e.receivedDelay, func() {
+				e.publish(event(StepRunning, time.Since(start), nil), extra...)
+			})
+
+			stepCtx := execCtx.ctx
+			if step.Timeout != "" {
+				// parseAndValidate already rejected an unparseable timeout,
+				// so d is always valid here.
+				d, _ := time.ParseDuration(step.Timeout)
+				var cancel context.CancelFunc
+				stepCtx, cancel = context.WithTimeout(stepCtx, d)
+				defer cancel()
+			}
+
+			result, err := e.dispatch(stepCtx, stepLog, step, fcfg)
+			debounce.Stop()
+
+			if err != nil {
+				stepLog.Error("step failed", "duration_ms", time.Since(start).Milliseconds(), "error", err.Error())
+				e.publish(event(StepFailed, time.Since(start), err), extra...)
+				recordErr(fmt.Errorf("step %s failed: %w", step.Name, err))
+				if step.FailFast {
+					execCtx.Cancel(fmt.Errorf("step %s failed with fail_fast", step.Name))
+					abortAll()
+				}
+				failedMu.Lock()
+				failed[step.Name] = true
+				failedMu.Unlock()
+				return
+			}
+
+			resultsMu.Lock()
+			results[step.Name] = result
+			resultsMu.Unlock()
+
+			if step.ResultPath != "" {
+				if err := writeResultFile(e.resolvePath(step.ResultPath), result); err != nil {
+					e.publish(event(StepFailed, time.Since(start), err), extra...)
+					recordErr(fmt.Errorf("write result for step %s: %w", step.Name, err))
+					failedMu.Lock()
+					failed[step.Name] = true
+					failedMu.Unlock()
+					return
+				}
+			}
+			stepLog.Info("step completed", "duration_ms", time.Since(start).Milliseconds(), "outputs", result)
+			e.publish(event(StepCompleted, time.Since(start), nil), extra...)
+		}()
+	}
+
+	wg.Wait()
+
+	resultsMu.RLock()
+	partial := snapshotResults(results)
+	resultsMu.RUnlock()
+
+	return partial, firstErr
+}
+
+// waitForAll blocks until every channel in chans is closed or abort
+// fires, whichever comes first. It reports whether abort won the race.
+func waitForAll(chans []<-chan struct{}, abort <-chan struct{}) bool {
+	for _, c := range chans {
+		select {
+		case <-c:
+		case <-abort:
+			return true
 		}
-		e.logf("[workflow] Step %s completed. Outputs: %v", step.Name, result)
 	}
+	return false
+}
 
-	return results, nil
+// snapshotResults copies results so a published event isn't aliased to a
+// map the executor keeps mutating after the event is sent.
+func snapshotResults(results map[string]map[string]any) map[string]map[string]any {
+	out := make(map[string]map[string]any, len(results))
+	for k, v := range results {
+		out[k] = v
+	}
+	return out
 }
 
-func (e *Executor) logf(format string, args ...any) {
-	if e.logger != nil {
-		e.logger(format, args...)
+// dispatch runs a step through the executor's dispatcher, retrying on a
+// different agent when a transport error occurs. It never retries an
+// error the FMU itself reported.
+func (e *Executor) dispatch(ctx context.Context, log logging.Logger, step workflowStep, cfg fmi.Config) (map[string]any, error) {
+	dispatcher := e.dispatcher
+	if len(step.RunOn) == 0 {
+		// Steps without run_on labels always run in-process; only labeled
+		// steps are eligible for remote dispatch.
+		dispatcher = LocalDispatcher{}
+	}
+	for attempt := 0; ; attempt++ {
+		result, err := dispatcher.Dispatch(ctx, step, cfg)
+		if err == nil {
+			return result, nil
+		}
+		if !errors.Is(err, ErrAgentTransport) || attempt >= e.maxRetries {
+			return nil, err
+		}
+
+		var te *transportError
+		if remote, ok := dispatcher.(*RemoteDispatcher); ok && errors.As(err, &te) {
+			dispatcher = remote.excluding(te.agentID)
+		}
+		log.Warn("dispatch failed, retrying on another agent", "attempt", attempt+1, "max_retries", e.maxRetries, "error", err.Error())
+		if e.retryWait > 0 {
+			select {
+			case <-time.After(e.retryWait):
+			case <-ctx.Done():
+				return nil, ctx.Err()
+			}
+		}
 	}
 }
 
@@ -145,6 +655,19 @@ type workflowStep struct {
 	ResultPath  string            `yaml:"result"`
 	StartValues map[string]any    `yaml:"start_values"`
 	StartFrom   map[string]string `yaml:"start_from"`
+	RunOn       RunOn             `yaml:"run_on"`
+	// DependsOn names the steps that must complete before this one
+	// starts. If no step in the file sets this, steps run in file order,
+	// one at a time, exactly as before depends_on existed.
+	DependsOn []string `yaml:"depends_on"`
+	// Timeout bounds how long this step's FMU call is allowed to run,
+	// e.g. "30s" or "5m". Parsed with time.ParseDuration; unset means no
+	// per-step limit beyond the run's own ExecutorContext.
+	Timeout string `yaml:"timeout"`
+	// FailFast cancels every other step's context (already running or
+	// still waiting) as soon as this step fails, instead of only
+	// skipping the steps that depend on it.
+	FailFast bool `yaml:"fail_fast"`
 }
 
 func (e *Executor) buildStartValues(step workflowStep, results map[string]map[string]any) (map[string]string, error) {
@@ -187,6 +710,29 @@ func (e *Executor) buildStartValues(step workflowStep, results map[string]map[st
 	return values, nil
 }
 
+// encodeLiteralStartValues encodes a step's literal start_values, without
+// resolving start_from (which needs another step's actual output). Used
+// by Plan, which reports bindings without running anything.
+func encodeLiteralStartValues(step workflowStep) (map[string]string, error) {
+	if len(step.StartValues) == 0 {
+		return nil, nil
+	}
+	values := make(map[string]string, len(step.StartValues))
+	keys := make([]string, 0, len(step.StartValues))
+	for key := range step.StartValues {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		encoded, err := encodeScalar(step.StartValues[key])
+		if err != nil {
+			return nil, fmt.Errorf("start_values[%s]: %w", key, err)
+		}
+		values[key] = encoded
+	}
+	return values, nil
+}
+
 func encodeScalar(value any) (string, error) {
 	switch v := value.(type) {
 	case nil:
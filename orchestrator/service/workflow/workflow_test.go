@@ -0,0 +1,179 @@
+package workflow
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/norceresearch/cads-fmi-demo/orchestrator/internal/fmi"
+)
+
+// stubDispatcher lets tests control each step's outcome without touching
+// the cgo fmi bridge. Steps must set run_on so the executor routes to it
+// instead of LocalDispatcher (see Executor.dispatch).
+type stubDispatcher struct {
+	mu      sync.Mutex
+	results map[string]map[string]any
+	errs    map[string]error
+	gate    map[string]<-chan struct{} // if set, Dispatch blocks until closed
+	calls   map[string]int
+}
+
+func (d *stubDispatcher) Dispatch(ctx context.Context, step workflowStep, _ fmi.Config) (map[string]any, error) {
+	d.mu.Lock()
+	if d.calls == nil {
+		d.calls = make(map[string]int)
+	}
+	d.calls[step.Name]++
+	d.mu.Unlock()
+
+	if gate, ok := d.gate[step.Name]; ok {
+		select {
+		case <-gate:
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+	}
+	if err, ok := d.errs[step.Name]; ok {
+		return nil, err
+	}
+	return d.results[step.Name], nil
+}
+
+// newTestExecutor creates an executor rooted at a temp directory with a
+// dummy FMU file on disk for each of the given step names, so
+// parseAndValidate's os.Stat check passes without a real FMU.
+func newTestExecutor(t *testing.T, dispatcher Dispatcher, stepNames []string, opts ...Option) (*Executor, string) {
+	t.Helper()
+	root := t.TempDir()
+	for _, name := range stepNames {
+		if err := os.WriteFile(filepath.Join(root, name+".fmu"), []byte("stub"), 0o644); err != nil {
+			t.Fatalf("write stub fmu: %v", err)
+		}
+	}
+	allOpts := append([]Option{WithDispatcher(dispatcher)}, opts...)
+	exec, err := NewExecutor(root, allOpts...)
+	if err != nil {
+		t.Fatalf("NewExecutor: %v", err)
+	}
+	return exec, root
+}
+
+func writeWorkflow(t *testing.T, root, name, yamlBody string) string {
+	t.Helper()
+	path := filepath.Join(root, name)
+	if err := os.WriteFile(path, []byte(yamlBody), 0o644); err != nil {
+		t.Fatalf("write workflow: %v", err)
+	}
+	return path
+}
+
+func TestRun_PartialResultsOnFailure(t *testing.T) {
+	stub := &stubDispatcher{
+		results: map[string]map[string]any{"ok": {"y": 1.0}},
+		errs:    map[string]error{"fails": errors.New("boom")},
+	}
+	exec, root := newTestExecutor(t, stub, []string{"ok", "fails"})
+	writeWorkflow(t, root, "wf.yaml", `
+steps:
+  - name: ok
+    fmu: ok.fmu
+    run_on: {pool: test}
+  - name: fails
+    fmu: fails.fmu
+    run_on: {pool: test}
+`)
+
+	results, err := exec.Run("wf.yaml")
+	if err == nil {
+		t.Fatal("expected an error from the failed step")
+	}
+	if _, ok := results["ok"]; !ok {
+		t.Fatalf("expected results to retain the successful step's output, got %#v", results)
+	}
+	if _, ok := results["fails"]; ok {
+		t.Fatalf("failed step should not have a results entry, got %#v", results)
+	}
+}
+
+// TestRun_IndependentBranchNotSkippedByUnrelatedFailure reproduces the
+// bug where a blanket abort channel, closed on any step's failure, would
+// skip steps on a completely unrelated branch of the DAG even without
+// fail_fast. "waiting" is gated on a channel the test only closes after
+// "fails" has already failed, so a buggy abort would reliably skip it.
+func TestRun_IndependentBranchNotSkippedByUnrelatedFailure(t *testing.T) {
+	gate := make(chan struct{})
+	stub := &stubDispatcher{
+		results: map[string]map[string]any{"gate": {}, "waiting": {"y": 1.0}},
+		errs:    map[string]error{"fails": errors.New("boom")},
+		gate:    map[string]<-chan struct{}{"gate": gate},
+	}
+	exec, root := newTestExecutor(t, stub, []string{"fails", "gate", "waiting"})
+	writeWorkflow(t, root, "wf.yaml", `
+steps:
+  - name: fails
+    fmu: fails.fmu
+    run_on: {pool: test}
+  - name: gate
+    fmu: gate.fmu
+    run_on: {pool: test}
+  - name: waiting
+    fmu: waiting.fmu
+    run_on: {pool: test}
+    depends_on: [gate]
+`)
+
+	done := make(chan struct{})
+	var results map[string]map[string]any
+	var runErr error
+	go func() {
+		results, runErr = exec.Run("wf.yaml")
+		close(done)
+	}()
+
+	// Give "fails" time to fail (and, pre-fix, close the blanket abort
+	// channel) before "waiting" is allowed to proceed past its
+	// dependency on "gate".
+	time.Sleep(50 * time.Millisecond)
+	close(gate)
+	<-done
+
+	if runErr == nil {
+		t.Fatal("expected an error from the failed step")
+	}
+	if _, ok := results["waiting"]; !ok {
+		t.Fatalf("independent branch should have run to completion despite the unrelated failure, got %#v", results)
+	}
+}
+
+func TestExecutor_dispatchRetryRespectsContextCancellation(t *testing.T) {
+	stub := &stubDispatcher{
+		errs: map[string]error{"flaky": fi(ErrAgentTransport)},
+	}
+	exec, _ := newTestExecutor(t, stub, nil, WithRetry(5, 2*time.Second))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	time.AfterFunc(20*time.Millisecond, cancel)
+
+	start := time.Now()
+	_, err := exec.dispatch(ctx, exec.log, workflowStep{Name: "flaky", RunOn: RunOn{"pool": "test"}}, fmi.Config{})
+	elapsed := time.Since(start)
+
+	if !errors.Is(err, context.Canceled) {
+		t.Fatalf("expected context.Canceled, got %v", err)
+	}
+	if elapsed > 500*time.Millisecond {
+		t.Fatalf("dispatch retry ignored context cancellation, took %s", elapsed)
+	}
+}
+
+// fi wraps err so errors.Is(result, ErrAgentTransport) succeeds, the way
+// a real transport error would via %w.
+func fi(err error) error {
+	return fmt.Errorf("transport: %w", err)
+}